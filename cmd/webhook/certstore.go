@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"log"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// certStore holds the TLS certificate currently being served behind an
+// atomic pointer, so GetCertificate never blocks on a reload in progress,
+// and keeps it fresh by watching the cert/key files for changes. This lets
+// cert-manager rotate the serving certificate without a pod restart.
+type certStore struct {
+	cert atomic.Pointer[tls.Certificate]
+}
+
+func newCertStore() *certStore {
+	return &certStore{}
+}
+
+func (s *certStore) load(certPath, keyPath string) error {
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return err
+	}
+	s.cert.Store(&cert)
+	return nil
+}
+
+func (s *certStore) loaded() bool {
+	return s.cert.Load() != nil
+}
+
+func (s *certStore) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return s.cert.Load(), nil
+}
+
+// watch reloads the certificate whenever certPath or keyPath changes, using
+// fsnotify and, if pollInterval is non-zero, a periodic poll as a backstop
+// for filesystems where the cert-manager atomic-rename pattern doesn't
+// surface cleanly through inotify. It runs until ctx is done.
+func (s *certStore) watch(ctx context.Context, certPath, keyPath string, pollInterval time.Duration) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Printf("Failed to start certificate file watcher, falling back to polling only: %v", err)
+		watcher = nil
+	} else {
+		defer watcher.Close()
+		for _, dir := range uniqueDirs(certPath, keyPath) {
+			if err := watcher.Add(dir); err != nil {
+				log.Printf("Failed to watch %s for certificate changes: %v", dir, err)
+			}
+		}
+	}
+
+	var tickerC <-chan time.Time
+	if pollInterval > 0 {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		tickerC = ticker.C
+	}
+
+	var events <-chan fsnotify.Event
+	var watchErrs <-chan error
+	if watcher != nil {
+		events = watcher.Events
+		watchErrs = watcher.Errors
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-events:
+			s.reload(certPath, keyPath)
+		case err := <-watchErrs:
+			log.Printf("Certificate watcher error: %v", err)
+		case <-tickerC:
+			s.reload(certPath, keyPath)
+		}
+	}
+}
+
+func (s *certStore) reload(certPath, keyPath string) {
+	if err := s.load(certPath, keyPath); err != nil {
+		log.Printf("Failed to reload TLS certificate, keeping previous one in use: %v", err)
+		return
+	}
+	log.Println("Reloaded TLS certificate")
+}
+
+func uniqueDirs(paths ...string) []string {
+	seen := map[string]bool{}
+	var dirs []string
+	for _, p := range paths {
+		dir := filepath.Dir(p)
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
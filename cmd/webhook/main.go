@@ -1,43 +1,135 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
+	"flag"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/example/webhook-conversion/pkg/webhook"
 )
 
 func main() {
-	certPath := os.Getenv("TLS_CERT_FILE")
-	keyPath := os.Getenv("TLS_PRIVATE_KEY_FILE")
+	certPath := flag.String("tls-cert-file", envOrDefault("TLS_CERT_FILE", "/etc/certs/tls.crt"), "Path to the TLS certificate file")
+	keyPath := flag.String("tls-private-key-file", envOrDefault("TLS_PRIVATE_KEY_FILE", "/etc/certs/tls.key"), "Path to the TLS private key file")
+	certReloadInterval := flag.Duration("tls-cert-reload-interval", 0, "Fallback polling interval for reloading the TLS certificate, in addition to watching the cert/key files for changes; 0 disables polling")
+	metricsAddr := flag.String("metrics-addr", envOrDefault("METRICS_ADDR", ":9090"), "Address to serve Prometheus metrics on, in plaintext, separate from the TLS webhook port")
+	probeAddr := flag.String("probe-addr", envOrDefault("PROBE_ADDR", ":8080"), "Address to serve /health and /readyz on, in plaintext, so probes work even before a TLS certificate has loaded")
+	flag.Parse()
 
-	if certPath == "" {
-		certPath = "/etc/certs/tls.crt"
-	}
-	if keyPath == "" {
-		keyPath = "/etc/certs/tls.key"
-	}
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
-	if err != nil {
-		log.Fatalf("Failed to load key pair: %v", err)
+	certs := newCertStore()
+	// A missing cert at startup isn't fatal: cert-manager may not have
+	// written it yet. certs.watch retries in the background, /readyz
+	// reports 503 until it succeeds, and we avoid crash-looping the whole
+	// process over a race we expect to resolve within seconds.
+	if err := certs.load(*certPath, *keyPath); err != nil {
+		log.Printf("Initial TLS certificate not yet available, waiting for it to appear: %v", err)
 	}
+	go certs.watch(ctx, *certPath, *keyPath, *certReloadInterval)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/convert", webhook.HandleConvert)
 
 	server := &http.Server{
-		Addr:      ":8443",
-		TLSConfig: &tls.Config{Certificates: []tls.Certificate{cert}},
+		Addr:    ":8443",
+		Handler: loggingMiddleware(mux),
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+		TLSConfig: &tls.Config{
+			GetCertificate: certs.getCertificate,
+		},
 	}
 
-	http.HandleFunc("/convert", webhook.HandleConvert)
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", promhttp.Handler())
+	metricsServer := &http.Server{
+		Addr:    *metricsAddr,
+		Handler: metricsMux,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
+
+	probeMux := http.NewServeMux()
+	probeMux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	})
+	probeMux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !certs.loaded() {
+			http.Error(w, "TLS certificate not yet loaded", http.StatusServiceUnavailable)
+			return
+		}
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	})
+	probeServer := &http.Server{
+		Addr:    *probeAddr,
+		Handler: probeMux,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
+
+	var wg sync.WaitGroup
+	for _, s := range []struct {
+		name   string
+		server *http.Server
+	}{
+		{"Metrics", metricsServer},
+		{"Probe", probeServer},
+	} {
+		wg.Add(1)
+		go func(name string, srv *http.Server) {
+			defer wg.Done()
+			log.Printf("%s server starting on %s", name, srv.Addr)
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("%s server error: %v", name, err)
+			}
+		}(s.name, s.server)
+	}
+
+	go func() {
+		<-ctx.Done()
+		log.Println("Shutdown signal received, draining in-flight requests")
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Error shutting down webhook server: %v", err)
+		}
+		if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Error shutting down metrics server: %v", err)
+		}
+		if err := probeServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Error shutting down probe server: %v", err)
+		}
+	}()
 
 	log.Println("Webhook server starting on :8443")
-	if err := server.ListenAndServeTLS("", ""); err != nil {
+	if err := server.ListenAndServeTLS("", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatalf("Failed to start webhook server: %v", err)
 	}
+	wg.Wait()
+}
+
+func envOrDefault(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
 }
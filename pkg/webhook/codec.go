@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"mime"
+
+	"github.com/munnerz/goautoneg"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer/json"
+)
+
+const (
+	mimeJSON = "application/json"
+	mimeYAML = "application/yaml"
+)
+
+// scheme knows about both ConversionReview group/versions so the decoder can
+// pick the right Go type based on the apiVersion/kind in the request body.
+var scheme = runtime.NewScheme()
+
+func init() {
+	if err := apiextensionsv1.AddToScheme(scheme); err != nil {
+		panic(err)
+	}
+	if err := apiextensionsv1beta1.AddToScheme(scheme); err != nil {
+		panic(err)
+	}
+}
+
+var (
+	jsonSerializer = json.NewSerializerWithOptions(json.DefaultMetaFactory, scheme, scheme, json.SerializerOptions{})
+	yamlSerializer = json.NewSerializerWithOptions(json.DefaultMetaFactory, scheme, scheme, json.SerializerOptions{Yaml: true})
+)
+
+// decoderForContentType returns the serializer that can decode a request body
+// of the given Content-Type, falling back to JSON when the header is missing
+// or unrecognized.
+func decoderForContentType(contentType string) runtime.Serializer {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+
+	if mediaType == mimeYAML {
+		return yamlSerializer
+	}
+	return jsonSerializer
+}
+
+// negotiateEncoder picks a response serializer by running the client's
+// Accept header through content negotiation, the same way the apiserver
+// negotiates webhook response bodies. It falls back to JSON.
+func negotiateEncoder(acceptHeader string) (runtime.Serializer, string) {
+	for _, accept := range goautoneg.ParseAccept(acceptHeader) {
+		mediaType := accept.Type + "/" + accept.SubType
+		if mediaType == mimeYAML {
+			return yamlSerializer, mimeYAML
+		}
+		if mediaType == mimeJSON {
+			return jsonSerializer, mimeJSON
+		}
+	}
+
+	return jsonSerializer, mimeJSON
+}
@@ -1,9 +1,59 @@
 package webhook
 
 import (
+	"fmt"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	exampleV1GV  = schema.GroupVersion{Group: "conversion.example.com", Version: "v1"}
+	exampleV2GV  = schema.GroupVersion{Group: "conversion.example.com", Version: "v2"}
+	exampleV1GVK = exampleV1GV.WithKind("Example")
+	exampleV2GVK = exampleV2GV.WithKind("Example")
 )
 
+// defaultRegistry holds every converter this webhook knows about. Adding a
+// new CRD version means registering a converter here, not editing the
+// handler.
+var defaultRegistry = NewRegistry()
+
+func init() {
+	defaultRegistry.Register(exampleV1GVK, exampleV2GV, convertExampleV1ToV2)
+	defaultRegistry.Register(exampleV2GVK, exampleV1GV, convertExampleV2ToV1)
+}
+
+func convertExampleV1ToV2(obj *unstructured.Unstructured, toGV schema.GroupVersion) (*unstructured.Unstructured, error) {
+	var v1Obj ExampleV1
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &v1Obj); err != nil {
+		return nil, fmt.Errorf("decoding ExampleV1: %w", err)
+	}
+
+	out, err := runtime.DefaultUnstructuredConverter.ToUnstructured(convertV1ToV2(&v1Obj))
+	if err != nil {
+		return nil, fmt.Errorf("encoding ExampleV2: %w", err)
+	}
+
+	return &unstructured.Unstructured{Object: out}, nil
+}
+
+func convertExampleV2ToV1(obj *unstructured.Unstructured, toGV schema.GroupVersion) (*unstructured.Unstructured, error) {
+	var v2Obj ExampleV2
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &v2Obj); err != nil {
+		return nil, fmt.Errorf("decoding ExampleV2: %w", err)
+	}
+
+	out, err := runtime.DefaultUnstructuredConverter.ToUnstructured(convertV2ToV1(&v2Obj))
+	if err != nil {
+		return nil, fmt.Errorf("encoding ExampleV1: %w", err)
+	}
+
+	return &unstructured.Unstructured{Object: out}, nil
+}
+
 func convertV1ToV2(v1Obj *ExampleV1) *ExampleV2 {
 	return &ExampleV2{
 		TypeMeta:   metav1.TypeMeta{APIVersion: "conversion.example.com/v2", Kind: "Example"},
@@ -27,3 +77,56 @@ func convertV2ToV1(v2Obj *ExampleV2) *ExampleV1 {
 		Status: v2Obj.Status,
 	}
 }
+
+// conversionFailure records an object that could not be converted, so the
+// caller can surface it in the response's Result instead of silently
+// dropping the object.
+type conversionFailure struct {
+	gvk     schema.GroupVersionKind
+	message string
+}
+
+// convertObjects converts each object to desiredAPIVersion via
+// defaultRegistry, passing through any object already on the desired
+// version. The apiserver treats a ConvertedObjects slice shorter than
+// Request.Objects as a malformed webhook response, so this is all-or-nothing:
+// if every object converts, the returned slice has exactly len(objects)
+// entries in the same order; if any object fails, it returns a nil slice and
+// every failure encountered, and the caller is expected to fail the whole
+// request rather than return a partial result.
+func convertObjects(objects []runtime.RawExtension, desiredAPIVersion string) ([]runtime.RawExtension, []conversionFailure) {
+	toGV, err := schema.ParseGroupVersion(desiredAPIVersion)
+	if err != nil {
+		return nil, []conversionFailure{{message: fmt.Sprintf("invalid desiredAPIVersion %q: %v", desiredAPIVersion, err)}}
+	}
+
+	convertedObjects := make([]runtime.RawExtension, len(objects))
+	var failures []conversionFailure
+
+	for i, obj := range objects {
+		u := &unstructured.Unstructured{}
+		if err := u.UnmarshalJSON(obj.Raw); err != nil {
+			failures = append(failures, conversionFailure{message: fmt.Sprintf("object %d: failed to unmarshal: %v", i, err)})
+			continue
+		}
+
+		converted, err := defaultRegistry.Convert(u, toGV)
+		if err != nil {
+			failures = append(failures, conversionFailure{gvk: u.GroupVersionKind(), message: err.Error()})
+			continue
+		}
+
+		convertedJSON, err := converted.MarshalJSON()
+		if err != nil {
+			failures = append(failures, conversionFailure{gvk: u.GroupVersionKind(), message: fmt.Sprintf("failed to marshal converted object: %v", err)})
+			continue
+		}
+
+		convertedObjects[i] = runtime.RawExtension{Raw: convertedJSON}
+	}
+
+	if len(failures) > 0 {
+		return nil, failures
+	}
+	return convertedObjects, nil
+}
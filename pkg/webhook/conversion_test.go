@@ -0,0 +1,97 @@
+package webhook
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestConvertObjects_UnmarshalableInput(t *testing.T) {
+	objects := []runtime.RawExtension{{Raw: []byte("not json")}}
+
+	converted, failures := convertObjects(objects, "conversion.example.com/v2")
+
+	if converted != nil {
+		t.Errorf("converted = %v, want nil on failure", converted)
+	}
+	if len(failures) != 1 {
+		t.Fatalf("len(failures) = %d, want 1", len(failures))
+	}
+}
+
+func TestConvertObjects_UnknownSourceGVK(t *testing.T) {
+	objects := []runtime.RawExtension{
+		{Raw: []byte(`{"apiVersion":"conversion.example.com/v9","kind":"Example","metadata":{"name":"a"}}`)},
+	}
+
+	converted, failures := convertObjects(objects, "conversion.example.com/v2")
+
+	if converted != nil {
+		t.Errorf("converted = %v, want nil on failure", converted)
+	}
+	if len(failures) != 1 {
+		t.Fatalf("len(failures) = %d, want 1", len(failures))
+	}
+}
+
+func TestConvertObjects_UnknownDesiredGV(t *testing.T) {
+	objects := []runtime.RawExtension{
+		{Raw: []byte(`{"apiVersion":"conversion.example.com/v1","kind":"Example","metadata":{"name":"a"},"spec":{"field1":"x"}}`)},
+	}
+
+	converted, failures := convertObjects(objects, "conversion.example.com/v9")
+
+	if converted != nil {
+		t.Errorf("converted = %v, want nil on failure", converted)
+	}
+	if len(failures) != 1 {
+		t.Fatalf("len(failures) = %d, want 1", len(failures))
+	}
+}
+
+func TestConvertObjects_SuccessfulMixedBatch(t *testing.T) {
+	objects := []runtime.RawExtension{
+		{Raw: []byte(`{"apiVersion":"conversion.example.com/v1","kind":"Example","metadata":{"name":"a"},"spec":{"field1":"x"}}`)},
+		{Raw: []byte(`{"apiVersion":"conversion.example.com/v2","kind":"Example","metadata":{"name":"b"},"spec":{"field1":"y","field2":"z"}}`)},
+	}
+
+	converted, failures := convertObjects(objects, "conversion.example.com/v2")
+
+	if len(failures) != 0 {
+		t.Fatalf("failures = %v, want none", failures)
+	}
+	if len(converted) != len(objects) {
+		t.Fatalf("len(converted) = %d, want %d", len(converted), len(objects))
+	}
+}
+
+func TestProcessConversion_FailureClearsConvertedObjects(t *testing.T) {
+	objects := []runtime.RawExtension{{Raw: []byte("not json")}}
+
+	converted, result := processConversion("some-uid", "conversion.example.com/v2", objects)
+
+	if converted != nil {
+		t.Errorf("converted = %v, want nil", converted)
+	}
+	if result.Status != "Failure" {
+		t.Errorf("result.Status = %q, want Failure", result.Status)
+	}
+	if result.Reason != "Invalid" {
+		t.Errorf("result.Reason = %q, want Invalid", result.Reason)
+	}
+}
+
+func TestProcessConversion_SuccessReturnsAllObjects(t *testing.T) {
+	objects := []runtime.RawExtension{
+		{Raw: []byte(`{"apiVersion":"conversion.example.com/v1","kind":"Example","metadata":{"name":"a"},"spec":{"field1":"x"}}`)},
+	}
+
+	converted, result := processConversion("some-uid", "conversion.example.com/v2", objects)
+
+	if result.Status != "Success" {
+		t.Errorf("result.Status = %q, want Success", result.Status)
+	}
+	if len(converted) != len(objects) {
+		t.Fatalf("len(converted) = %d, want %d", len(converted), len(objects))
+	}
+}
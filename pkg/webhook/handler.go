@@ -1,16 +1,25 @@
 package webhook
 
 import (
-	"encoding/json"
+	"bytes"
+	"fmt"
 	"io"
-	"log"
 	"net/http"
 
+	"go.uber.org/zap"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// HandleConvert serves CRD conversion webhook requests. It accepts both the
+// apiextensions.k8s.io/v1 and v1beta1 ConversionReview envelopes, since older
+// apiservers and mixed-version test harnesses may still POST the beta form,
+// and always responds on the same group/version it was called with. Request
+// and response bodies are negotiated independently: Content-Type selects how
+// the request is decoded, Accept selects how the response is encoded, and
+// both JSON and YAML are supported.
 func HandleConvert(w http.ResponseWriter, r *http.Request) {
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -18,72 +27,107 @@ func HandleConvert(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var review apiextensionsv1.ConversionReview
-	if err := json.Unmarshal(body, &review); err != nil {
+	decoder := decoderForContentType(r.Header.Get("Content-Type"))
+	encoder, responseContentType := negotiateEncoder(r.Header.Get("Accept"))
+
+	obj, gvk, err := decoder.Decode(body, nil, nil)
+	if err != nil {
+		if runtime.IsNotRegisteredError(err) {
+			// The body parsed fine but named a group/version/kind we don't
+			// serve conversions for. The apiserver expects a well-formed
+			// Failure envelope here, not an HTTP error.
+			Logger.Warn("rejecting ConversionReview with unsupported group/version", zap.Error(err))
+			writeFailureResponse(w, encoder, responseContentType, fmt.Sprintf("unsupported ConversionReview: %v", err))
+			return
+		}
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	switch review := obj.(type) {
+	case *apiextensionsv1.ConversionReview:
+		handleConvertV1(w, review, encoder, responseContentType)
+	case *apiextensionsv1beta1.ConversionReview:
+		handleConvertV1beta1(w, review, encoder, responseContentType)
+	default:
+		Logger.Warn("rejecting ConversionReview with unsupported group/version", zap.Any("gvk", gvk))
+		writeFailureResponse(w, encoder, responseContentType, fmt.Sprintf("unsupported ConversionReview group/version %v", gvk))
+	}
+}
+
+func handleConvertV1(w http.ResponseWriter, review *apiextensionsv1.ConversionReview, encoder runtime.Serializer, contentType string) {
 	req := review.Request
-	convertedObjects := []runtime.RawExtension{}
+	if req == nil {
+		review.Response = &apiextensionsv1.ConversionResponse{
+			Result: metav1.Status{Status: "Failure", Message: "ConversionReview.request is required"},
+		}
+		writeReview(w, encoder, contentType, review)
+		return
+	}
 
-	for _, obj := range req.Objects {
-		var converted runtime.Object
+	convertedObjects, result := processConversion(req.UID, req.DesiredAPIVersion, req.Objects)
 
-		// Parse the original object to determine its version
-		var objMeta metav1.TypeMeta
-		if err := json.Unmarshal(obj.Raw, &objMeta); err != nil {
-			log.Printf("Failed to unmarshal object metadata: %v", err)
-			continue
-		}
+	review.Response = &apiextensionsv1.ConversionResponse{
+		UID:              req.UID,
+		ConvertedObjects: convertedObjects,
+		Result:           result,
+	}
+	review.Request = nil
 
-		switch {
-		case objMeta.APIVersion == "conversion.example.com/v1" && req.DesiredAPIVersion == "conversion.example.com/v2":
-			var v1Obj ExampleV1
-			if err := json.Unmarshal(obj.Raw, &v1Obj); err != nil {
-				log.Printf("Failed to unmarshal v1 object: %v", err)
-				continue
-			}
-			converted = convertV1ToV2(&v1Obj)
-
-		case objMeta.APIVersion == "conversion.example.com/v2" && req.DesiredAPIVersion == "conversion.example.com/v1":
-			var v2Obj ExampleV2
-			if err := json.Unmarshal(obj.Raw, &v2Obj); err != nil {
-				log.Printf("Failed to unmarshal v2 object: %v", err)
-				continue
-			}
-			converted = convertV2ToV1(&v2Obj)
-
-		default:
-			// No conversion needed, return original
-			convertedObjects = append(convertedObjects, obj)
-			continue
-		}
+	writeReview(w, encoder, contentType, review)
+}
 
-		convertedJSON, err := json.Marshal(converted)
-		if err != nil {
-			log.Printf("Failed to marshal converted object: %v", err)
-			continue
+func handleConvertV1beta1(w http.ResponseWriter, review *apiextensionsv1beta1.ConversionReview, encoder runtime.Serializer, contentType string) {
+	req := review.Request
+	if req == nil {
+		review.Response = &apiextensionsv1beta1.ConversionResponse{
+			Result: metav1.Status{Status: "Failure", Message: "ConversionReview.request is required"},
 		}
-
-		convertedObjects = append(convertedObjects, runtime.RawExtension{Raw: convertedJSON})
+		writeReview(w, encoder, contentType, review)
+		return
 	}
 
-	response := &apiextensionsv1.ConversionResponse{
+	convertedObjects, result := processConversion(req.UID, req.DesiredAPIVersion, req.Objects)
+
+	review.Response = &apiextensionsv1beta1.ConversionResponse{
 		UID:              req.UID,
 		ConvertedObjects: convertedObjects,
-		Result:           metav1.Status{Status: "Success"},
+		Result:           result,
 	}
-
-	review.Response = response
 	review.Request = nil
 
-	respBytes, err := json.Marshal(review)
-	if err != nil {
+	writeReview(w, encoder, contentType, review)
+}
+
+// writeFailureResponse rejects a ConversionReview we couldn't decode into a
+// typed v1 or v1beta1 object at all, e.g. one naming an unsupported
+// group/version/kind. Since we never got a typed review to respond on, we
+// fall back to the v1 envelope. We respond with a well-formed Failure status
+// rather than an HTTP error code, since that's what the apiserver expects
+// from a webhook that can't service the request.
+func writeFailureResponse(w http.ResponseWriter, encoder runtime.Serializer, contentType, message string) {
+	review := &apiextensionsv1.ConversionReview{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "apiextensions.k8s.io/v1",
+			Kind:       "ConversionReview",
+		},
+		Response: &apiextensionsv1.ConversionResponse{
+			Result: metav1.Status{
+				Status:  "Failure",
+				Message: message,
+			},
+		},
+	}
+	writeReview(w, encoder, contentType, review)
+}
+
+func writeReview(w http.ResponseWriter, encoder runtime.Serializer, contentType string, review runtime.Object) {
+	var buf bytes.Buffer
+	if err := encoder.Encode(review, &buf); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(respBytes)
+	w.Header().Set("Content-Type", contentType)
+	w.Write(buf.Bytes())
 }
@@ -0,0 +1,194 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+)
+
+func TestHandleConvert_SupportedVersions(t *testing.T) {
+	tests := []struct {
+		name       string
+		apiVersion string
+		body       string
+	}{
+		{
+			name:       "v1",
+			apiVersion: "apiextensions.k8s.io/v1",
+			body: `{
+				"apiVersion": "apiextensions.k8s.io/v1",
+				"kind": "ConversionReview",
+				"request": {
+					"uid": "11111111-1111-1111-1111-111111111111",
+					"desiredAPIVersion": "conversion.example.com/v2",
+					"objects": [
+						{"apiVersion":"conversion.example.com/v1","kind":"Example","metadata":{"name":"a"},"spec":{"field1":"x"}}
+					]
+				}
+			}`,
+		},
+		{
+			name:       "v1beta1",
+			apiVersion: "apiextensions.k8s.io/v1beta1",
+			body: `{
+				"apiVersion": "apiextensions.k8s.io/v1beta1",
+				"kind": "ConversionReview",
+				"request": {
+					"uid": "22222222-2222-2222-2222-222222222222",
+					"desiredAPIVersion": "conversion.example.com/v2",
+					"objects": [
+						{"apiVersion":"conversion.example.com/v1","kind":"Example","metadata":{"name":"b"},"spec":{"field1":"y"}}
+					]
+				}
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doConvert(t, "application/json", "application/json", tt.body)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+			}
+
+			var envelope struct {
+				APIVersion string `json:"apiVersion"`
+				Kind       string `json:"kind"`
+				Response   struct {
+					UID              string `json:"uid"`
+					Result           struct{ Status string }
+					ConvertedObjects []json.RawMessage `json:"convertedObjects"`
+				} `json:"response"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+				t.Fatalf("unmarshal response: %v, body = %s", err, rec.Body.String())
+			}
+
+			if envelope.APIVersion != tt.apiVersion {
+				t.Errorf("response apiVersion = %q, want %q", envelope.APIVersion, tt.apiVersion)
+			}
+			if envelope.Kind != "ConversionReview" {
+				t.Errorf("response kind = %q, want ConversionReview", envelope.Kind)
+			}
+			if envelope.Response.Result.Status != "Success" {
+				t.Errorf("result status = %q, want Success", envelope.Response.Result.Status)
+			}
+			if len(envelope.Response.ConvertedObjects) != 1 {
+				t.Fatalf("len(convertedObjects) = %d, want 1", len(envelope.Response.ConvertedObjects))
+			}
+		})
+	}
+}
+
+func TestHandleConvert_UnsupportedGroupVersion(t *testing.T) {
+	body := `{"apiVersion":"apiextensions.k8s.io/v2","kind":"ConversionReview","request":{"uid":"x","desiredAPIVersion":"conversion.example.com/v2","objects":[]}}`
+
+	rec := doConvert(t, "application/json", "application/json", body)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (failures are reported in the body, not as an HTTP error), body = %s", rec.Code, rec.Body.String())
+	}
+
+	var review apiextensionsv1.ConversionReview
+	if err := json.Unmarshal(rec.Body.Bytes(), &review); err != nil {
+		t.Fatalf("unmarshal response: %v, body = %s", err, rec.Body.String())
+	}
+	if review.Response == nil || review.Response.Result.Status != "Failure" {
+		t.Fatalf("response = %+v, want a Failure result", review.Response)
+	}
+}
+
+func TestHandleConvert_MissingRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		apiVersion string
+		body       string
+	}{
+		{
+			name:       "v1",
+			apiVersion: "apiextensions.k8s.io/v1",
+			body:       `{"apiVersion":"apiextensions.k8s.io/v1","kind":"ConversionReview"}`,
+		},
+		{
+			name:       "v1beta1",
+			apiVersion: "apiextensions.k8s.io/v1beta1",
+			body:       `{"apiVersion":"apiextensions.k8s.io/v1beta1","kind":"ConversionReview"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doConvert(t, "application/json", "application/json", tt.body)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200, body = %s", rec.Code, rec.Body.String())
+			}
+
+			var envelope struct {
+				APIVersion string `json:"apiVersion"`
+				Response   struct {
+					Result struct{ Status string }
+				} `json:"response"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+				t.Fatalf("unmarshal response: %v, body = %s", err, rec.Body.String())
+			}
+
+			if envelope.APIVersion != tt.apiVersion {
+				t.Errorf("response apiVersion = %q, want %q (failure responses must echo the request's group/version)", envelope.APIVersion, tt.apiVersion)
+			}
+			if envelope.Response.Result.Status != "Failure" {
+				t.Fatalf("result status = %q, want Failure", envelope.Response.Result.Status)
+			}
+		})
+	}
+}
+
+func TestHandleConvert_V1beta1EnvelopeRoundTrip(t *testing.T) {
+	body := `{
+		"apiVersion": "apiextensions.k8s.io/v1beta1",
+		"kind": "ConversionReview",
+		"request": {
+			"uid": "33333333-3333-3333-3333-333333333333",
+			"desiredAPIVersion": "conversion.example.com/v1",
+			"objects": [
+				{"apiVersion":"conversion.example.com/v2","kind":"Example","metadata":{"name":"c"},"spec":{"field1":"x","field2":"y"}}
+			]
+		}
+	}`
+
+	rec := doConvert(t, "application/json", "application/json", body)
+
+	var review apiextensionsv1beta1.ConversionReview
+	if err := json.Unmarshal(rec.Body.Bytes(), &review); err != nil {
+		t.Fatalf("unmarshal response: %v, body = %s", err, rec.Body.String())
+	}
+
+	if review.Response == nil {
+		t.Fatal("response is nil")
+	}
+	if string(review.Response.UID) != "33333333-3333-3333-3333-333333333333" {
+		t.Errorf("UID = %q, want echoed UID", review.Response.UID)
+	}
+	if review.Response.Result.Status != "Success" {
+		t.Errorf("result status = %q, want Success", review.Response.Result.Status)
+	}
+}
+
+func doConvert(t *testing.T, contentType, accept, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/convert", strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", accept)
+
+	rec := httptest.NewRecorder()
+	HandleConvert(rec, req)
+	return rec
+}
@@ -0,0 +1,17 @@
+package webhook
+
+import "go.uber.org/zap"
+
+// Logger is the structured logger used for request-scoped logging
+// throughout the webhook. It's exported so cmd/main can reuse the same
+// logger for its HTTP request middleware instead of configuring a second
+// one.
+var Logger *zap.Logger
+
+func init() {
+	l, err := zap.NewProduction()
+	if err != nil {
+		l = zap.NewNop()
+	}
+	Logger = l
+}
@@ -0,0 +1,111 @@
+package webhook
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var (
+	conversionRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "conversion_requests_total",
+		Help: "Total number of CRD conversion requests handled, labeled by source/desired API version and result.",
+	}, []string{"from", "to", "result"})
+
+	conversionObjectsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "conversion_objects_total",
+		Help: "Total number of objects processed during CRD conversion, labeled by source/desired API version and result.",
+	}, []string{"from", "to", "result"})
+
+	conversionRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "conversion_request_duration_seconds",
+		Help:    "Latency of CRD conversion requests, labeled by source/desired API version.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"from", "to"})
+)
+
+// processConversion runs convertObjects for a single ConversionRequest,
+// recording Prometheus metrics and emitting a structured log entry for the
+// request as a whole plus one for each object that failed to convert.
+func processConversion(uid types.UID, desiredAPIVersion string, objects []runtime.RawExtension) ([]runtime.RawExtension, metav1.Status) {
+	start := time.Now()
+	from := predominantSourceGV(objects)
+
+	convertedObjects, failures := convertObjects(objects, desiredAPIVersion)
+
+	result := "success"
+	if len(failures) > 0 {
+		result = "failure"
+	}
+	conversionRequestsTotal.WithLabelValues(from, desiredAPIVersion, result).Inc()
+	conversionObjectsTotal.WithLabelValues(from, desiredAPIVersion, "converted").Add(float64(len(convertedObjects)))
+	conversionObjectsTotal.WithLabelValues(from, desiredAPIVersion, "failed").Add(float64(len(failures)))
+	conversionRequestDuration.WithLabelValues(from, desiredAPIVersion).Observe(time.Since(start).Seconds())
+
+	Logger.Info("handled conversion request",
+		zap.String("uid", string(uid)),
+		zap.String("desiredAPIVersion", desiredAPIVersion),
+		zap.String("sourceGroupVersion", from),
+		zap.Int("objectCount", len(objects)),
+		zap.Int("convertedCount", len(convertedObjects)),
+		zap.Int("failureCount", len(failures)),
+		zap.Duration("duration", time.Since(start)),
+	)
+	for _, f := range failures {
+		Logger.Error("object conversion failed",
+			zap.String("uid", string(uid)),
+			zap.String("gvk", f.gvk.String()),
+			zap.String("error", f.message),
+		)
+	}
+
+	return convertedObjects, resultFromFailures(failures)
+}
+
+// resultFromFailures builds the response Result for a batch of conversions.
+// The apiserver rejects a ConvertedObjects slice shorter than the request's
+// Objects as a malformed webhook response, so any per-object failure fails
+// the whole request rather than returning a partial result.
+func resultFromFailures(failures []conversionFailure) metav1.Status {
+	if len(failures) == 0 {
+		return metav1.Status{Status: "Success"}
+	}
+
+	messages := make([]string, len(failures))
+	for i, f := range failures {
+		if f.gvk.Empty() {
+			messages[i] = f.message
+		} else {
+			messages[i] = f.gvk.String() + ": " + f.message
+		}
+	}
+
+	return metav1.Status{
+		Status:  "Failure",
+		Message: strings.Join(messages, "; "),
+		Reason:  metav1.StatusReasonInvalid,
+	}
+}
+
+// predominantSourceGV returns the apiVersion of the first object in the
+// batch, used as the "from" metric label. Only the first object is inspected:
+// in a batch that mixes source versions, the other objects' versions are not
+// reflected in the label.
+func predominantSourceGV(objects []runtime.RawExtension) string {
+	if len(objects) == 0 {
+		return "unknown"
+	}
+
+	var meta metav1.TypeMeta
+	if err := json.Unmarshal(objects[0].Raw, &meta); err != nil || meta.APIVersion == "" {
+		return "unknown"
+	}
+	return meta.APIVersion
+}
@@ -0,0 +1,111 @@
+package webhook
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Converter converts a single unstructured object to toGV.
+type Converter func(obj *unstructured.Unstructured, toGV schema.GroupVersion) (*unstructured.Unstructured, error)
+
+// conversionKey identifies a registered pairwise converter by the GVK it
+// converts from and the GV it converts to.
+type conversionKey struct {
+	from schema.GroupVersionKind
+	to   schema.GroupVersion
+}
+
+// Registry maps (fromGVK, toGV) pairs to converters and can chain adjacent
+// pairwise converters together when no direct converter is registered, e.g.
+// resolving v1 -> v3 as v1 -> v2 -> v3.
+type Registry struct {
+	converters map[conversionKey]Converter
+	// versions lists every group/version known for a kind, keyed by kind, so
+	// the chain resolver has a search space to walk.
+	versions map[string][]schema.GroupVersion
+}
+
+// NewRegistry returns an empty conversion Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		converters: map[conversionKey]Converter{},
+		versions:   map[string][]schema.GroupVersion{},
+	}
+}
+
+// Register adds a converter from fromGVK directly to toGV.
+func (r *Registry) Register(fromGVK schema.GroupVersionKind, toGV schema.GroupVersion, conv Converter) {
+	r.converters[conversionKey{from: fromGVK, to: toGV}] = conv
+	r.addVersion(fromGVK.Kind, fromGVK.GroupVersion())
+	r.addVersion(fromGVK.Kind, toGV)
+}
+
+func (r *Registry) addVersion(kind string, gv schema.GroupVersion) {
+	for _, existing := range r.versions[kind] {
+		if existing == gv {
+			return
+		}
+	}
+	r.versions[kind] = append(r.versions[kind], gv)
+}
+
+// Convert converts obj to toGV, using a direct converter if one is
+// registered or composing a chain of registered converters otherwise.
+func (r *Registry) Convert(obj *unstructured.Unstructured, toGV schema.GroupVersion) (*unstructured.Unstructured, error) {
+	fromGVK := obj.GroupVersionKind()
+	if fromGVK.GroupVersion() == toGV {
+		return obj, nil
+	}
+
+	path, err := r.resolvePath(fromGVK, toGV)
+	if err != nil {
+		return nil, err
+	}
+
+	current := obj
+	for _, step := range path {
+		converted, err := r.converters[step](current, step.to)
+		if err != nil {
+			return nil, fmt.Errorf("converting %s to %s: %w", step.from, step.to, err)
+		}
+		current = converted
+	}
+
+	return current, nil
+}
+
+// resolvePath does a breadth-first search over fromGVK.Kind's registered
+// converters to find a chain from fromGVK to toGV.
+func (r *Registry) resolvePath(fromGVK schema.GroupVersionKind, toGV schema.GroupVersion) ([]conversionKey, error) {
+	type node struct {
+		gv   schema.GroupVersion
+		path []conversionKey
+	}
+
+	visited := map[schema.GroupVersion]bool{fromGVK.GroupVersion(): true}
+	queue := []node{{gv: fromGVK.GroupVersion()}}
+
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+
+		for _, next := range r.versions[fromGVK.Kind] {
+			key := conversionKey{from: n.gv.WithKind(fromGVK.Kind), to: next}
+			if _, ok := r.converters[key]; !ok || visited[next] {
+				continue
+			}
+
+			path := append(append([]conversionKey{}, n.path...), key)
+			if next == toGV {
+				return path, nil
+			}
+
+			visited[next] = true
+			queue = append(queue, node{gv: next, path: path})
+		}
+	}
+
+	return nil, fmt.Errorf("no registered conversion path from %s to %s", fromGVK, toGV)
+}